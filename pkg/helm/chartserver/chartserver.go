@@ -0,0 +1,138 @@
+// Package chartserver serves charts helmper has pulled, rewritten and
+// pushed to a target registry back out as a classic Helm chart repository,
+// so downstream consumers can `helm repo add`/`helm install` against
+// helmper's mirrored output.
+package chartserver
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/provenance"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+	"sigs.k8s.io/yaml"
+)
+
+// Server is a read-only HTTP server implementing the classic Helm chart
+// repository protocol: `GET /index.yaml` and `GET /charts/{name}-{version}.tgz`.
+type Server struct {
+	// BaseURL is the externally reachable URL charts are served from, used
+	// to build each entry's download URL in index.yaml, e.g.
+	// "https://mirror.example.com/charts".
+	BaseURL string
+
+	dir string // directory repackaged .tgz archives are written to/served from
+
+	mu     sync.RWMutex
+	charts map[string]*chart.Chart // "name-version" -> chart
+}
+
+// NewServer returns a Server that repackages added charts into dir.
+func NewServer(dir, baseURL string) (*Server, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create chart server directory %s: %w", dir, err)
+	}
+	return &Server{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		dir:     dir,
+		charts:  make(map[string]*chart.Chart),
+	}, nil
+}
+
+// Add repackages c, whose Values are presumed already rewritten (e.g. via
+// helmper's replaceImageReferences), into dir and makes it available from
+// both /index.yaml and /charts/{name}-{version}.tgz.
+func (s *Server) Add(c *chart.Chart) error {
+	if _, err := chartutil.Save(c, s.dir); err != nil {
+		return fmt.Errorf("failed to repackage chart %s: %w", key(c), err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.charts[key(c)] = c
+	return nil
+}
+
+func key(c *chart.Chart) string {
+	return fmt.Sprintf("%s-%s", c.Name(), c.Metadata.Version)
+}
+
+func filename(c *chart.Chart) string {
+	return key(c) + ".tgz"
+}
+
+// Handler returns the http.Handler implementing the repository protocol.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/index.yaml", s.serveIndex)
+	mux.HandleFunc("/charts/", s.serveChart)
+	return mux
+}
+
+func (s *Server) serveIndex(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	idx := repo.NewIndexFile()
+	for _, c := range s.charts {
+		archivePath := filepath.Join(s.dir, filename(c))
+		digest, err := provenance.DigestFile(archivePath)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to digest %s: %v", filename(c), err), http.StatusInternalServerError)
+			return
+		}
+		if err := idx.Add(c.Metadata, filename(c), s.BaseURL, digest); err != nil {
+			http.Error(w, fmt.Sprintf("failed to index %s: %v", filename(c), err), http.StatusInternalServerError)
+			return
+		}
+	}
+	idx.SortEntries()
+
+	data, err := yaml.Marshal(idx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to marshal index.yaml: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/yaml")
+	_, _ = w.Write(data)
+}
+
+func (s *Server) serveChart(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/charts/")
+	if name == "" || !strings.HasSuffix(name, ".tgz") || strings.Contains(name, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeFile(w, r, filepath.Join(s.dir, name))
+}
+
+// PublishOCI additionally pushes every chart Add has repackaged to ociBase
+// (e.g. "oci://registry.example.com/charts") via client, so
+// `helm install oci://...` works against the same mirrored chart set.
+func (s *Server) PublishOCI(client *registry.Client, ociBase string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	base := strings.TrimPrefix(strings.TrimSuffix(ociBase, "/"), "oci://")
+
+	for _, c := range s.charts {
+		data, err := os.ReadFile(filepath.Join(s.dir, filename(c)))
+		if err != nil {
+			return fmt.Errorf("failed to read repackaged chart %s: %w", filename(c), err)
+		}
+
+		ref := fmt.Sprintf("%s/%s:%s", base, c.Name(), c.Metadata.Version)
+		if _, err := client.Push(data, ref); err != nil {
+			return fmt.Errorf("failed to push chart %s to %s: %w", filename(c), ref, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package chartserver
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func testChart(name, version string) *chart.Chart {
+	return &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       name,
+			Version:    version,
+		},
+	}
+}
+
+func TestKeyAndFilename(t *testing.T) {
+	c := testChart("demo", "1.2.3")
+	if got, want := key(c), "demo-1.2.3"; got != want {
+		t.Errorf("key: got %q, want %q", got, want)
+	}
+	if got, want := filename(c), "demo-1.2.3.tgz"; got != want {
+		t.Errorf("filename: got %q, want %q", got, want)
+	}
+}
+
+func TestServerServeIndex(t *testing.T) {
+	s, err := NewServer(t.TempDir(), "https://mirror.example.com/charts")
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	if err := s.Add(testChart("demo", "1.2.3")); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/index.yaml")
+	if err != nil {
+		t.Fatalf("GET /index.yaml: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /index.yaml: got status %d", resp.StatusCode)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "demo-1.2.3.tgz") {
+		t.Errorf("index.yaml missing chart entry: %s", body)
+	}
+}
+
+func TestServerServeChart(t *testing.T) {
+	s, err := NewServer(t.TempDir(), "https://mirror.example.com/charts")
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	if err := s.Add(testChart("demo", "1.2.3")); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/charts/demo-1.2.3.tgz")
+	if err != nil {
+		t.Fatalf("GET chart: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET chart: got status %d", resp.StatusCode)
+	}
+}
+
+func TestServeChartRejectsPathTraversal(t *testing.T) {
+	s, err := NewServer(t.TempDir(), "https://mirror.example.com/charts")
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+
+	cases := []string{
+		"/charts/../secret.txt",
+		"/charts/../../etc/passwd",
+		"/charts/",
+		"/charts/not-a-tgz",
+	}
+
+	for _, path := range cases {
+		t.Run(path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			w := httptest.NewRecorder()
+			s.serveChart(w, req)
+			if w.Code != http.StatusNotFound {
+				t.Errorf("got status %d, want %d", w.Code, http.StatusNotFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package helm
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ImageLocator extracts image references from resources at locations the
+// typed PodSpec extractor in parser.go does not know about. This is the
+// escape hatch for CRDs such as a Kafka operator's `KafkaCluster` that embed
+// an image string outside of any PodSpec, e.g. `spec.template.spec.image`.
+type ImageLocator interface {
+	// GVK identifies the resource kind this locator applies to.
+	GVK() schema.GroupVersionKind
+	// Locate returns the image strings found in obj, a rendered manifest
+	// decoded to a generic map.
+	Locate(obj map[string]any) []string
+}
+
+// JSONPathImageLocator locates images at a dotted field path such as
+// "spec.template.spec.image". A segment suffixed with "[]" traverses a list
+// of objects rather than indexing a single field, e.g. "spec.containers[].image".
+type JSONPathImageLocator struct {
+	Group, Version, Kind string
+	Path                 string
+}
+
+func (l JSONPathImageLocator) GVK() schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: l.Group, Version: l.Version, Kind: l.Kind}
+}
+
+func (l JSONPathImageLocator) Locate(obj map[string]any) []string {
+	return locateAtPath(obj, strings.Split(l.Path, "."))
+}
+
+func locateAtPath(node any, segments []string) []string {
+	if len(segments) == 0 {
+		if s, ok := node.(string); ok && s != "" {
+			return []string{s}
+		}
+		return nil
+	}
+
+	seg := segments[0]
+	list := strings.HasSuffix(seg, "[]")
+	key := strings.TrimSuffix(seg, "[]")
+
+	m, ok := node.(map[string]any)
+	if !ok {
+		return nil
+	}
+	v, found := m[key]
+	if !found {
+		return nil
+	}
+
+	if list {
+		items, ok := v.([]any)
+		if !ok {
+			return nil
+		}
+		var out []string
+		for _, item := range items {
+			out = append(out, locateAtPath(item, segments[1:])...)
+		}
+		return out
+	}
+
+	return locateAtPath(v, segments[1:])
+}
+
+// Rewrite runs rewrite over every image string locator l finds in obj,
+// replacing each one with rewrite's return value.
+func (l JSONPathImageLocator) Rewrite(obj map[string]any, rewrite func(string) string) {
+	setAtPath(obj, strings.Split(l.Path, "."), rewrite)
+}
+
+func setAtPath(node any, segments []string, rewrite func(string) string) {
+	m, ok := node.(map[string]any)
+	if !ok || len(segments) == 0 {
+		return
+	}
+
+	seg := segments[0]
+	list := strings.HasSuffix(seg, "[]")
+	key := strings.TrimSuffix(seg, "[]")
+
+	v, found := m[key]
+	if !found {
+		return
+	}
+
+	if len(segments) == 1 {
+		if list {
+			items, ok := v.([]any)
+			if !ok {
+				return
+			}
+			for i, item := range items {
+				if s, ok := item.(string); ok {
+					items[i] = rewrite(s)
+				}
+			}
+			return
+		}
+		if s, ok := v.(string); ok {
+			m[key] = rewrite(s)
+		}
+		return
+	}
+
+	if list {
+		items, ok := v.([]any)
+		if !ok {
+			return
+		}
+		for _, item := range items {
+			setAtPath(item, segments[1:], rewrite)
+		}
+		return
+	}
+
+	setAtPath(v, segments[1:], rewrite)
+}
+
+// LocatorRegistry holds user-configured ImageLocators keyed by GroupVersionKind.
+// It is consulted for resources the typed PodSpec extractor does not
+// recognise, which in practice means CRDs.
+type LocatorRegistry struct {
+	locators map[schema.GroupVersionKind][]ImageLocator
+}
+
+// NewLocatorRegistry returns an empty LocatorRegistry ready for Register calls.
+func NewLocatorRegistry() *LocatorRegistry {
+	return &LocatorRegistry{locators: make(map[schema.GroupVersionKind][]ImageLocator)}
+}
+
+// Register adds a locator for its own GVK.
+func (r *LocatorRegistry) Register(l ImageLocator) {
+	r.locators[l.GVK()] = append(r.locators[l.GVK()], l)
+}
+
+// Locate runs every locator registered for gvk against obj.
+func (r *LocatorRegistry) Locate(gvk schema.GroupVersionKind, obj map[string]any) []string {
+	if r == nil {
+		return nil
+	}
+	var out []string
+	for _, l := range r.locators[gvk] {
+		out = append(out, l.Locate(obj)...)
+	}
+	return out
+}
+
+// Rewrite runs every JSONPathImageLocator registered for gvk against obj,
+// replacing each image string it finds with rewrite's return value.
+func (r *LocatorRegistry) Rewrite(gvk schema.GroupVersionKind, obj map[string]any, rewrite func(string) string) {
+	if r == nil {
+		return
+	}
+	for _, l := range r.locators[gvk] {
+		if jp, ok := l.(JSONPathImageLocator); ok {
+			jp.Rewrite(obj, rewrite)
+		}
+	}
+}
+
+// ImageLocatorConfig is the shape of a single `imageLocators` entry in
+// helmper's config file, e.g.:
+//
+//	imageLocators:
+//	  - group: kafka.strimzi.io
+//	    version: v1beta2
+//	    kind: KafkaCluster
+//	    path: spec.template.spec.image
+type ImageLocatorConfig struct {
+	Group   string `mapstructure:"group"`
+	Version string `mapstructure:"version"`
+	Kind    string `mapstructure:"kind"`
+	Path    string `mapstructure:"path"`
+}
+
+// LocatorRegistryFromConfig builds a LocatorRegistry from the `imageLocators`
+// section of helmper's config file.
+func LocatorRegistryFromConfig(entries []ImageLocatorConfig) *LocatorRegistry {
+	r := NewLocatorRegistry()
+	for _, e := range entries {
+		r.Register(JSONPathImageLocator{
+			Group:   e.Group,
+			Version: e.Version,
+			Kind:    e.Kind,
+			Path:    e.Path,
+		})
+	}
+	return r
+}
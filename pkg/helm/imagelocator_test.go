@@ -0,0 +1,80 @@
+package helm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJSONPathImageLocatorLocate(t *testing.T) {
+	cases := []struct {
+		name string
+		path string
+		obj  map[string]any
+		want []string
+	}{
+		{
+			name: "single field",
+			path: "spec.template.spec.image",
+			obj: map[string]any{
+				"spec": map[string]any{
+					"template": map[string]any{
+						"spec": map[string]any{"image": "foo:1"},
+					},
+				},
+			},
+			want: []string{"foo:1"},
+		},
+		{
+			name: "list traversal",
+			path: "spec.containers[].image",
+			obj: map[string]any{
+				"spec": map[string]any{
+					"containers": []any{
+						map[string]any{"image": "a:1"},
+						map[string]any{"image": "b:2"},
+					},
+				},
+			},
+			want: []string{"a:1", "b:2"},
+		},
+		{
+			name: "missing path",
+			path: "spec.image",
+			obj:  map[string]any{"spec": map[string]any{}},
+			want: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := JSONPathImageLocator{Path: tc.path}
+			got := l.Locate(tc.obj)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestJSONPathImageLocatorRewrite(t *testing.T) {
+	obj := map[string]any{
+		"spec": map[string]any{
+			"containers": []any{
+				map[string]any{"image": "a:1"},
+				map[string]any{"image": "b:2"},
+			},
+		},
+	}
+
+	l := JSONPathImageLocator{Path: "spec.containers[].image"}
+	l.Rewrite(obj, func(s string) string { return "registry.example.com/" + s })
+
+	want := []string{"registry.example.com/a:1", "registry.example.com/b:2"}
+	containers := obj["spec"].(map[string]any)["containers"].([]any)
+	for i, c := range containers {
+		got := c.(map[string]any)["image"].(string)
+		if got != want[i] {
+			t.Errorf("container %d: got %q, want %q", i, got, want[i])
+		}
+	}
+}
@@ -14,6 +14,13 @@ import (
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/postrender"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
 )
 
 // traverse helm chart values to determine if condition is met
@@ -168,36 +175,39 @@ func findImageReferences(data map[string]any, values map[string]any, useCustomVa
 	return findImageReferencesAcc(data, values, useCustomValues, "")
 }
 
-// traverse helm chart values data structure
-func replaceImageReferences(data map[string]any, reg string, prefixSource bool) {
-
-	// For images we do not use the prefix and suffix of the registry
-	reg, _ = strings.CutPrefix(reg, "oci://")
-
-	convert := func(val string) string {
-		ref, err := reference.ParseAnyReference(val)
-		if err != nil {
-			return ""
-		}
-		r := ref.(reference.Named)
-		dom := reference.Domain(r)
+// rewriteImageRef rewrites a single image reference to target registry reg,
+// optionally prefixing the rewritten repository with the first label of the
+// image's original registry host, without port (e.g. "docker" for docker.io),
+// so that images from different upstream registries don't collide once
+// mirrored under one target registry.
+func rewriteImageRef(val string, reg string, prefixSource bool) string {
+	ref, err := reference.ParseAnyReference(val)
+	if err != nil {
+		return ""
+	}
+	r := ref.(reference.Named)
+	dom := reference.Domain(r)
 
+	if prefixSource {
 		source := strings.Split(dom, ":")[0]
 		source = strings.Split(source, ".")[0]
-		source = "/" + source
-		if prefixSource {
-			reg = reg + source
-		}
+		reg = reg + "/" + source
+	}
 
-		if strings.Contains(val, dom) {
-			return strings.Replace(ref.String(), dom, reg, 1)
-		} else {
-			if strings.HasPrefix(ref.String(), "docker.io/library/") {
-				return reg + "/library/" + val
-			}
-			return reg + "/" + val
-		}
+	if strings.Contains(val, dom) {
+		return strings.Replace(ref.String(), dom, reg, 1)
+	}
+	if strings.HasPrefix(ref.String(), "docker.io/library/") {
+		return reg + "/library/" + val
 	}
+	return reg + "/" + val
+}
+
+// traverse helm chart values data structure
+func replaceImageReferences(data map[string]any, reg string, prefixSource bool) {
+
+	// For images we do not use the prefix and suffix of the registry
+	reg, _ = strings.CutPrefix(reg, "oci://")
 
 	old, ok := data["registry"].(string)
 	if ok {
@@ -217,13 +227,13 @@ func replaceImageReferences(data map[string]any, reg string, prefixSource bool)
 
 	image, ok := data["image"].(string)
 	if ok {
-		data["image"] = convert(image)
+		data["image"] = rewriteImageRef(image, reg, prefixSource)
 		return
 	}
 
 	repository, ok := data["repository"].(string)
 	if ok {
-		data["repository"] = convert(repository)
+		data["repository"] = rewriteImageRef(repository, reg, prefixSource)
 		return
 	}
 
@@ -236,8 +246,9 @@ func replaceImageReferences(data map[string]any, reg string, prefixSource bool)
 	}
 }
 
-// renderHelmTemplate renders a helm chart using helm template action and returns the manifests
-func renderHelmTemplate(chartRef *chart.Chart, values map[string]any, settings *cli.EnvSettings, releaseName string, namespace string, kubeVersion string) (string, error) {
+// renderHelmTemplate renders a helm chart using helm template action and
+// returns the manifests, optionally passing them through postRenderer.
+func renderHelmTemplate(chartRef *chart.Chart, values map[string]any, settings *cli.EnvSettings, releaseName string, namespace string, kubeVersion string, postRenderer postrender.PostRenderer) (string, error) {
 	actionConfig := new(action.Configuration)
 	if err := actionConfig.Init(settings.RESTClientGetter(), namespace, "memory", log.Printf); err != nil {
 		return "", fmt.Errorf("failed to initialize action configuration: %w", err)
@@ -250,6 +261,7 @@ func renderHelmTemplate(chartRef *chart.Chart, values map[string]any, settings *
 	install.Replace = true
 	install.ClientOnly = true
 	install.KubeVersion = &chartutil.KubeVersion{Version: kubeVersion}
+	install.PostRenderer = postRenderer
 
 	// Render the chart with the provided values
 	release, err := install.Run(chartRef, values)
@@ -260,80 +272,135 @@ func renderHelmTemplate(chartRef *chart.Chart, values map[string]any, settings *
 	return release.Manifest, nil
 }
 
-// findImageReferencesFromManifest extracts image references from rendered Kubernetes manifests
-func findImageReferencesFromManifest(manifest string) (map[*image.Image][]string, error) {
-	result := make(map[*image.Image][]string)
+// containerImageRef is a single image string found in a rendered manifest,
+// together with a human-readable path describing where it was found.
+type containerImageRef struct {
+	path string
+	ref  string
+}
 
-	// Split manifest into individual documents
-	documents := strings.Split(manifest, "---")
+// findImageReferencesFromManifest extracts image references from a manifest
+// rendered by renderHelmTemplate. Known workload kinds are decoded via the
+// k8s.io/api scheme; other kinds fall back to locators.
+func findImageReferencesFromManifest(manifest string, locators *LocatorRegistry) (map[*image.Image][]string, error) {
+	result := make(map[*image.Image][]string)
+	decoder := scheme.Codecs.UniversalDeserializer()
 
-	for _, doc := range documents {
+	for _, doc := range strings.Split(manifest, "---") {
 		doc = strings.TrimSpace(doc)
 		if doc == "" {
 			continue
 		}
 
-		// Parse the YAML document
-		var k8sResource map[string]interface{}
-		if err := yaml.Unmarshal([]byte(doc), &k8sResource); err != nil {
+		var raw map[string]any
+		if err := yaml.Unmarshal([]byte(doc), &raw); err != nil || raw == nil {
 			continue // Skip invalid YAML
 		}
 
-		// Extract images from this document
-		images := extractImagesFromResource(k8sResource)
-		for _, imgStr := range images {
-			img, err := image.RefToImage(imgStr)
+		var refs []containerImageRef
+
+		if obj, gvk, err := decoder.Decode([]byte(doc), nil, nil); err == nil {
+			refs = podSpecImageRefs(gvk.Kind, resourceName(raw), obj)
+		}
+
+		if len(refs) == 0 {
+			gv, _ := schema.ParseGroupVersion(stringField(raw, "apiVersion"))
+			kind := stringField(raw, "kind")
+			for _, imgStr := range locators.Locate(gv.WithKind(kind), raw) {
+				refs = append(refs, containerImageRef{
+					path: fmt.Sprintf("%s/%s/%s", kind, resourceName(raw), imgStr),
+					ref:  imgStr,
+				})
+			}
+		}
+
+		for _, ref := range refs {
+			img, err := image.RefToImage(ref.ref)
 			if err != nil {
 				continue // Skip invalid image references
 			}
-
-			// Generate a path based on the resource type and name
-			path := generateResourcePath(k8sResource, imgStr)
-			result[&img] = append(result[&img], path)
+			result[&img] = append(result[&img], ref.path)
 		}
 	}
 
 	return result, nil
 }
 
-// extractImagesFromResource recursively extracts image references from a Kubernetes resource
-func extractImagesFromResource(resource interface{}) []string {
-	var images []string
+// podSpecImageRefs extracts images from obj's PodSpec, whether obj is a Pod
+// or a workload controller wrapping a PodTemplateSpec.
+func podSpecImageRefs(kind, name string, obj runtime.Object) []containerImageRef {
+	switch o := obj.(type) {
+	case *corev1.Pod:
+		return podSpecRefs(kind, name, "spec", o.Spec)
+	case *appsv1.Deployment:
+		return podSpecRefs(kind, name, "spec.template.spec", o.Spec.Template.Spec)
+	case *appsv1.DaemonSet:
+		return podSpecRefs(kind, name, "spec.template.spec", o.Spec.Template.Spec)
+	case *appsv1.StatefulSet:
+		return podSpecRefs(kind, name, "spec.template.spec", o.Spec.Template.Spec)
+	case *appsv1.ReplicaSet:
+		return podSpecRefs(kind, name, "spec.template.spec", o.Spec.Template.Spec)
+	case *corev1.ReplicationController:
+		return podSpecRefs(kind, name, "spec.template.spec", o.Spec.Template.Spec)
+	case *batchv1.Job:
+		return podSpecRefs(kind, name, "spec.template.spec", o.Spec.Template.Spec)
+	case *batchv1.CronJob:
+		return podSpecRefs(kind, name, "spec.jobTemplate.spec.template.spec", o.Spec.JobTemplate.Spec.Template.Spec)
+	default:
+		return nil
+	}
+}
 
-	switch v := resource.(type) {
-	case map[string]interface{}:
-		for key, value := range v {
-			if key == "image" {
-				if imgStr, ok := value.(string); ok && imgStr != "" {
-					images = append(images, imgStr)
-				}
-			} else {
-				images = append(images, extractImagesFromResource(value)...)
-			}
-		}
-	case []interface{}:
-		for _, item := range v {
-			images = append(images, extractImagesFromResource(item)...)
+// podSpecRefs converts every container image in ps into a containerImageRef,
+// rooted at fieldPath.
+func podSpecRefs(kind, name, fieldPath string, ps corev1.PodSpec) []containerImageRef {
+	var refs []containerImageRef
+
+	add := func(field, container, img string) {
+		if img == "" {
+			return
 		}
+		refs = append(refs, containerImageRef{
+			path: fmt.Sprintf("%s/%s/%s.%s[%s].image", kind, name, fieldPath, field, container),
+			ref:  img,
+		})
 	}
 
-	return images
-}
+	for _, c := range ps.InitContainers {
+		add("initContainers", c.Name, c.Image)
+	}
+	for _, c := range ps.Containers {
+		add("containers", c.Name, c.Image)
+	}
+	for _, c := range ps.EphemeralContainers {
+		add("ephemeralContainers", c.Name, c.Image)
+	}
 
-// generateResourcePath creates a descriptive path for the image reference
-func generateResourcePath(resource map[string]interface{}, imageRef string) string {
-	kind := "unknown"
-	name := "unknown"
+	return refs
+}
 
-	if k, ok := resource["kind"].(string); ok {
-		kind = k
+// resourceName reads metadata.name from a generic decoded manifest document.
+func resourceName(resource map[string]any) string {
+	if name := stringField(resource, "metadata", "name"); name != "" {
+		return name
 	}
+	return "unknown"
+}
 
-	if metadata, ok := resource["metadata"].(map[string]interface{}); ok {
-		if n, ok := metadata["name"].(string); ok {
-			name = n
+// stringField reads a nested string field from a generic decoded manifest
+// document, returning "" if any segment of path is absent or not a string/map.
+func stringField(resource map[string]any, path ...string) string {
+	var cur any = resource
+	for _, p := range path {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return ""
+		}
+		cur, ok = m[p]
+		if !ok {
+			return ""
 		}
 	}
-
-	return fmt.Sprintf("%s/%s/image=%s", kind, name, imageRef)
+	s, _ := cur.(string)
+	return s
 }
@@ -0,0 +1,55 @@
+package helm
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPodSpecRefs(t *testing.T) {
+	ps := corev1.PodSpec{
+		InitContainers: []corev1.Container{
+			{Name: "init", Image: "busybox:1.0"},
+		},
+		Containers: []corev1.Container{
+			{Name: "app", Image: "nginx:1.21"},
+			{Name: "empty", Image: ""},
+		},
+		EphemeralContainers: []corev1.EphemeralContainer{
+			{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debug", Image: "busybox:debug"}},
+		},
+	}
+
+	refs := podSpecRefs("Deployment", "web", "spec.template.spec", ps)
+
+	want := map[string]string{
+		"Deployment/web/spec.template.spec.initContainers[init].image":       "busybox:1.0",
+		"Deployment/web/spec.template.spec.containers[app].image":            "nginx:1.21",
+		"Deployment/web/spec.template.spec.ephemeralContainers[debug].image": "busybox:debug",
+	}
+
+	if len(refs) != len(want) {
+		t.Fatalf("got %d refs, want %d: %+v", len(refs), len(want), refs)
+	}
+	for _, r := range refs {
+		wantRef, ok := want[r.path]
+		if !ok {
+			t.Errorf("unexpected path %q", r.path)
+			continue
+		}
+		if r.ref != wantRef {
+			t.Errorf("path %s: got ref %q, want %q", r.path, r.ref, wantRef)
+		}
+	}
+}
+
+func TestPodSpecRefsSkipsEmptyImages(t *testing.T) {
+	ps := corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "app", Image: ""}},
+	}
+
+	refs := podSpecRefs("Pod", "web", "spec", ps)
+	if len(refs) != 0 {
+		t.Errorf("got %d refs, want 0: %+v", len(refs), refs)
+	}
+}
@@ -0,0 +1,124 @@
+package helm
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"helm.sh/helm/v3/pkg/postrender"
+)
+
+// workloadPodSpecPaths maps a built-in workload Kind to the dotted path of
+// its embedded (or own) PodSpec.
+var workloadPodSpecPaths = map[string]string{
+	"Pod":                   "spec",
+	"Deployment":            "spec.template.spec",
+	"DaemonSet":             "spec.template.spec",
+	"StatefulSet":           "spec.template.spec",
+	"ReplicaSet":            "spec.template.spec",
+	"ReplicationController": "spec.template.spec",
+	"Job":                   "spec.template.spec",
+	"CronJob":               "spec.jobTemplate.spec.template.spec",
+}
+
+// podSpecContainerFields are the PodSpec fields that hold container images.
+var podSpecContainerFields = []string{"containers", "initContainers", "ephemeralContainers"}
+
+// ImageRewritePostRenderer is a helm.sh/helm/v3/pkg/postrender.PostRenderer
+// that rewrites every image reference in a rendered manifest stream to
+// TargetRegistry.
+type ImageRewritePostRenderer struct {
+	TargetRegistry string
+	PrefixSource   bool
+	Locators       *LocatorRegistry
+}
+
+// NewImageRewritePostRenderer returns a PostRenderer that rewrites every
+// discovered image to targetRegistry. locators may be nil.
+func NewImageRewritePostRenderer(targetRegistry string, prefixSource bool, locators *LocatorRegistry) *ImageRewritePostRenderer {
+	return &ImageRewritePostRenderer{
+		TargetRegistry: strings.TrimPrefix(targetRegistry, "oci://"),
+		PrefixSource:   prefixSource,
+		Locators:       locators,
+	}
+}
+
+// Run implements postrender.PostRenderer.
+func (p *ImageRewritePostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	var out []string
+
+	for _, doc := range strings.Split(renderedManifests.String(), "---") {
+		trimmed := strings.TrimSpace(doc)
+		if trimmed == "" {
+			continue
+		}
+
+		rewritten, err := p.rewriteDocument(trimmed)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rewritten)
+	}
+
+	return bytes.NewBufferString(strings.Join(out, "---\n")), nil
+}
+
+// rewriteDocument rewrites every image reference in doc: built-in workload
+// kinds are rewritten at their known PodSpec path, everything else falls
+// back to the configured locators.
+func (p *ImageRewritePostRenderer) rewriteDocument(doc string) (string, error) {
+	var raw map[string]any
+	if err := yamlv3.Unmarshal([]byte(doc), &raw); err != nil || raw == nil {
+		return doc, nil
+	}
+
+	kind := stringField(raw, "kind")
+
+	rewrite := func(ref string) string {
+		if ref == "" {
+			return ref
+		}
+		if rewritten := rewriteImageRef(ref, p.TargetRegistry, p.PrefixSource); rewritten != "" {
+			return rewritten
+		}
+		// Not a parseable reference; leave it untouched rather than erasing it.
+		return ref
+	}
+
+	if base, ok := workloadPodSpecPaths[kind]; ok {
+		for _, field := range podSpecContainerFields {
+			path := fmt.Sprintf("%s.%s[].image", base, field)
+			setAtPath(raw, strings.Split(path, "."), rewrite)
+		}
+	} else {
+		gv, _ := schema.ParseGroupVersion(stringField(raw, "apiVersion"))
+		p.Locators.Rewrite(gv.WithKind(kind), raw, rewrite)
+	}
+
+	b, err := yamlv3.Marshal(raw)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal rewritten manifest: %w", err)
+	}
+	return string(b), nil
+}
+
+// Chain runs a sequence of PostRenderers, feeding each one's output to the
+// next, e.g. helm.Chain{helm.NewImageRewritePostRenderer(...), myKustomizeRenderer}
+// mirrors `helm install --post-renderer` piping into a second post-renderer.
+type Chain []postrender.PostRenderer
+
+// Run implements postrender.PostRenderer.
+func (c Chain) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	out := renderedManifests
+	for _, r := range c {
+		var err error
+		out, err = r.Run(out)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,266 @@
+package helm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/provenance"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// ChartRef identifies a chart to resolve from a repository: a classic HTTP(S)
+// chart repo URL or an `oci://` registry reference, a chart name within that
+// repo, and a semver version constraint (e.g. ">=1.2.0 <2.0.0").
+// For HTTP(S) repos an empty VersionConstraint resolves to the newest
+// available version; for `oci://` refs VersionConstraint must be an exact
+// version, and an empty one or a range is rejected.
+type ChartRef struct {
+	Repo              string
+	Name              string
+	VersionConstraint string
+}
+
+// ChartResolver resolves a ChartRef to a loaded *chart.Chart, downloading and
+// caching classic HTTP repo index files, or pulling OCI artifacts, so callers
+// of renderHelmTemplate can supply a ChartRef instead of a pre-loaded chart
+// from a local path.
+type ChartResolver struct {
+	Getters getter.Providers
+
+	// CacheDir is where downloaded index.yaml and chart archives are cached.
+	CacheDir string
+	// CacheTTL is how long a cached index.yaml is trusted before helmper
+	// re-downloads it.
+	CacheTTL time.Duration
+
+	// VerifyKeyring, if set, is the PGP keyring path used to verify a
+	// chart's provenance file when the repository publishes one.
+	VerifyKeyring string
+}
+
+// NewChartResolver returns a ChartResolver configured with settings' getter
+// providers and an XDG-compliant cache directory, caching index files for
+// one hour by default.
+func NewChartResolver(settings *cli.EnvSettings) *ChartResolver {
+	return &ChartResolver{
+		Getters:  getter.All(settings),
+		CacheDir: xdgCacheDir(),
+		CacheTTL: time.Hour,
+	}
+}
+
+func xdgCacheDir() string {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "helmper")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "helmper")
+	}
+	return filepath.Join(home, ".cache", "helmper")
+}
+
+// repoCacheKey derives a stable, filesystem-safe cache key for repoURL.
+func repoCacheKey(repoURL string) string {
+	sum := sha256.Sum256([]byte(repoURL))
+	return hex.EncodeToString(sum[:])
+}
+
+// Resolve downloads ref and returns the loaded chart. For HTTP(S) repos the
+// index.yaml is reused from CacheDir while younger than CacheTTL; the chart
+// archive itself, and any OCI chart, is downloaded again on every call.
+func (r *ChartResolver) Resolve(ref ChartRef) (*chart.Chart, error) {
+	if err := os.MkdirAll(r.CacheDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create chart cache directory %s: %w", r.CacheDir, err)
+	}
+
+	if strings.HasPrefix(ref.Repo, "oci://") {
+		return r.resolveOCI(ref)
+	}
+	return r.resolveHTTP(ref)
+}
+
+// resolveHTTP resolves ref against a classic HTTP(S) chart repository's
+// index.yaml.
+func (r *ChartResolver) resolveHTTP(ref ChartRef) (*chart.Chart, error) {
+	idx, err := r.indexFile(ref.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	cv, err := selectVersion(idx, ref.Name, ref.VersionConstraint)
+	if err != nil {
+		return nil, err
+	}
+	if len(cv.URLs) == 0 {
+		return nil, fmt.Errorf("chart %q version %s has no download URL in the repository index", ref.Name, cv.Version)
+	}
+
+	chartURL, err := repo.ResolveReferenceURL(ref.Repo, cv.URLs[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve chart URL for %q: %w", ref.Name, err)
+	}
+
+	data, err := r.get(chartURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download chart %s: %w", chartURL, err)
+	}
+
+	archivePath := filepath.Join(r.CacheDir, fmt.Sprintf("%s-%s.tgz", ref.Name, cv.Version))
+	if err := os.WriteFile(archivePath, data, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to cache chart archive %s: %w", archivePath, err)
+	}
+
+	if r.VerifyKeyring != "" {
+		if err := r.verifyProvenance(archivePath, chartURL); err != nil {
+			return nil, fmt.Errorf("provenance verification failed for %q: %w", ref.Name, err)
+		}
+	}
+
+	c, err := loader.Load(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart %s: %w", archivePath, err)
+	}
+	return c, nil
+}
+
+// indexFile returns repoURL's index.yaml, using a cached copy when it is
+// younger than r.CacheTTL. The cache key is derived from repoURL itself
+// rather than any chart name, so every chart resolved from the same repo
+// shares one cache entry, and repos with colliding chart names never share
+// one another's cached index.
+func (r *ChartResolver) indexFile(repoURL string) (*repo.IndexFile, error) {
+	cacheKey := repoCacheKey(repoURL)
+
+	cr, err := repo.NewChartRepository(&repo.Entry{Name: cacheKey, URL: repoURL}, r.Getters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up repository %s: %w", repoURL, err)
+	}
+	cr.CachePath = r.CacheDir
+
+	idxPath := filepath.Join(r.CacheDir, helmpath.CacheIndexFile(cacheKey))
+	if info, err := os.Stat(idxPath); err == nil && time.Since(info.ModTime()) < r.CacheTTL {
+		return repo.LoadIndexFile(idxPath)
+	}
+
+	if _, err := cr.DownloadIndexFile(); err != nil {
+		return nil, fmt.Errorf("failed to download index.yaml for %s: %w", repoURL, err)
+	}
+	return repo.LoadIndexFile(idxPath)
+}
+
+// selectVersion picks the highest version of name in idx matching
+// constraintStr, or the newest version if constraintStr is empty.
+func selectVersion(idx *repo.IndexFile, name, constraintStr string) (*repo.ChartVersion, error) {
+	idx.SortEntries()
+
+	versions, ok := idx.Entries[name]
+	if !ok || len(versions) == 0 {
+		return nil, fmt.Errorf("chart %q not found in repository index", name)
+	}
+
+	if constraintStr == "" {
+		return versions[0], nil
+	}
+
+	constraint, err := semver.NewConstraint(constraintStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version constraint %q: %w", constraintStr, err)
+	}
+
+	for _, cv := range versions {
+		v, err := semver.NewVersion(cv.Version)
+		if err != nil {
+			continue
+		}
+		if constraint.Check(v) {
+			return cv, nil
+		}
+	}
+	return nil, fmt.Errorf("no version of chart %q matches constraint %q", name, constraintStr)
+}
+
+// verifyProvenance fetches chartURL's sibling .prov file, if published, and
+// verifies archivePath against it using r.VerifyKeyring.
+func (r *ChartResolver) verifyProvenance(archivePath, chartURL string) error {
+	data, err := r.get(chartURL + ".prov")
+	if err != nil {
+		// No provenance file published for this chart; nothing to verify.
+		return nil
+	}
+
+	provPath := archivePath + ".prov"
+	if err := os.WriteFile(provPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to cache provenance file %s: %w", provPath, err)
+	}
+
+	sig, err := provenance.NewFromKeyring(r.VerifyKeyring, "")
+	if err != nil {
+		return fmt.Errorf("failed to load keyring %s: %w", r.VerifyKeyring, err)
+	}
+	_, err = sig.Verify(archivePath, provPath)
+	return err
+}
+
+// get downloads rawURL using the getter registered for its scheme.
+func (r *ChartResolver) get(rawURL string) ([]byte, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %s: %w", rawURL, err)
+	}
+
+	g, err := r.Getters.ByScheme(parsed.Scheme)
+	if err != nil {
+		return nil, fmt.Errorf("no getter registered for scheme %q: %w", parsed.Scheme, err)
+	}
+
+	buf, err := g.Get(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resolveOCI resolves ref against an `oci://` registry. OCI registries have
+// no equivalent of index.yaml, so ref.VersionConstraint must be an exact
+// semver version rather than a range.
+func (r *ChartResolver) resolveOCI(ref ChartRef) (*chart.Chart, error) {
+	if ref.VersionConstraint == "" {
+		return nil, fmt.Errorf("OCI chart %q requires an explicit version; version ranges are not supported over OCI", ref.Name)
+	}
+	if _, err := semver.NewVersion(ref.VersionConstraint); err != nil {
+		return nil, fmt.Errorf("OCI chart %q requires an exact version, got constraint %q: %w", ref.Name, ref.VersionConstraint, err)
+	}
+
+	client, err := registry.NewClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OCI registry client: %w", err)
+	}
+
+	host := strings.TrimSuffix(strings.TrimPrefix(ref.Repo, "oci://"), "/")
+	ociRef := fmt.Sprintf("%s/%s:%s", host, ref.Name, ref.VersionConstraint)
+	pullResult, err := client.Pull(ociRef, registry.PullOptWithChart(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull OCI chart %s: %w", ociRef, err)
+	}
+
+	c, err := loader.LoadArchive(bytes.NewReader(pullResult.Chart.Data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OCI chart %s: %w", ociRef, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,52 @@
+package helm
+
+import (
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+func TestSelectVersion(t *testing.T) {
+	idx := &repo.IndexFile{
+		Entries: map[string]repo.ChartVersions{
+			"nginx": {
+				{Metadata: &chart.Metadata{Version: "1.2.0"}},
+				{Metadata: &chart.Metadata{Version: "1.3.0"}},
+				{Metadata: &chart.Metadata{Version: "2.0.0"}},
+			},
+		},
+	}
+
+	cases := []struct {
+		name       string
+		chart      string
+		constraint string
+		want       string
+		wantErr    bool
+	}{
+		{name: "empty constraint picks newest", chart: "nginx", constraint: "", want: "2.0.0"},
+		{name: "constraint picks highest match", chart: "nginx", constraint: "<2.0.0", want: "1.3.0"},
+		{name: "no version matches", chart: "nginx", constraint: ">3.0.0", wantErr: true},
+		{name: "invalid constraint", chart: "nginx", constraint: "not-a-constraint", wantErr: true},
+		{name: "unknown chart", chart: "missing", constraint: "", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cv, err := selectVersion(idx, tc.chart, tc.constraint)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cv.Version != tc.want {
+				t.Errorf("got version %s, want %s", cv.Version, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package helm
+
+import (
+	"fmt"
+
+	"github.com/ChristofferNissen/helmper/pkg/image"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+// findImageReferencesAcrossChart discovers images in chart c and recursively
+// in every enabled subchart, scoping each subchart's values under its alias
+// and applying Helm's own global/condition/tags/import-values propagation
+// rules so subchart image discovery sees exactly the values `helm template`
+// would give it.
+func findImageReferencesAcrossChart(c *chart.Chart, values map[string]any, settings *cli.EnvSettings, locators *LocatorRegistry) (map[*image.Image][]string, error) {
+	return findImageReferencesInChart(c, values, settings, locators, c.Name())
+}
+
+// findImageReferencesInChart is the recursive worker behind
+// findImageReferencesAcrossChart. chartPath accumulates the dotted chain of
+// chart names/aliases from the root chart down to c, so a discovered image's
+// paths can be told apart, e.g. "wordpress.mariadb" vs "wordpress.memcached".
+func findImageReferencesInChart(c *chart.Chart, values map[string]any, settings *cli.EnvSettings, locators *LocatorRegistry, chartPath string) (map[*image.Image][]string, error) {
+	merged, err := chartutil.CoalesceValues(c, values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to coalesce values for chart %s: %w", chartPath, err)
+	}
+
+	// Prunes disabled dependencies from c.Dependencies(), honors tags/condition
+	// gating, and applies Chart.yaml `import-values`. merged is mutated in place.
+	if err := chartutil.ProcessDependencies(c, merged); err != nil {
+		return nil, fmt.Errorf("failed to process dependencies for chart %s: %w", chartPath, err)
+	}
+
+	res, err := renderOwnImageReferences(c, merged, settings, locators, chartPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dep := range c.Dependencies() {
+		alias := dependencyAlias(c, dep)
+		depValues, _ := merged[alias].(map[string]any)
+
+		childRes, err := findImageReferencesInChart(dep, depValues, settings, locators, fmt.Sprintf("%s.%s", chartPath, alias))
+		if err != nil {
+			return nil, err
+		}
+		for img, paths := range childRes {
+			res[img] = append(res[img], paths...)
+		}
+	}
+
+	return res, nil
+}
+
+// renderOwnImageReferences renders c's own templates, temporarily detaching
+// its subcharts (findImageReferencesInChart recurses into those separately)
+// so the manifest only contains c's resources, then decodes it through the
+// same PodSpec-aware extractor renderHelmTemplate/findImageReferencesFromManifest
+// use everywhere else in the package.
+func renderOwnImageReferences(c *chart.Chart, values map[string]any, settings *cli.EnvSettings, locators *LocatorRegistry, chartPath string) (map[*image.Image][]string, error) {
+	deps := c.Dependencies()
+	c.SetDependencies()
+	defer c.SetDependencies(deps...)
+
+	manifest, err := renderHelmTemplate(c, values, settings, "helmper", "default", chartutil.DefaultCapabilities.KubeVersion.Version, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render chart %s: %w", chartPath, err)
+	}
+
+	found, err := findImageReferencesFromManifest(manifest, locators)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[*image.Image][]string, len(found))
+	for img, paths := range found {
+		for _, p := range paths {
+			res[img] = append(res[img], fmt.Sprintf("%s/%s", chartPath, p))
+		}
+	}
+	return res, nil
+}
+
+// dependencyAlias returns the alias parent's Chart.yaml gives dependency dep,
+// falling back to dep's own chart name when no alias is configured.
+func dependencyAlias(parent *chart.Chart, dep *chart.Chart) string {
+	if parent.Metadata != nil {
+		for _, d := range parent.Metadata.Dependencies {
+			if d.Name == dep.Name() && d.Alias != "" {
+				return d.Alias
+			}
+		}
+	}
+	return dep.Name()
+}
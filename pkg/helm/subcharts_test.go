@@ -0,0 +1,46 @@
+package helm
+
+import (
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestDependencyAlias(t *testing.T) {
+	dep := &chart.Chart{Metadata: &chart.Metadata{Name: "mariadb"}}
+
+	cases := []struct {
+		name   string
+		parent *chart.Chart
+		want   string
+	}{
+		{
+			name: "alias configured",
+			parent: &chart.Chart{Metadata: &chart.Metadata{
+				Dependencies: []*chart.Dependency{{Name: "mariadb", Alias: "db"}},
+			}},
+			want: "db",
+		},
+		{
+			name: "no alias configured",
+			parent: &chart.Chart{Metadata: &chart.Metadata{
+				Dependencies: []*chart.Dependency{{Name: "mariadb"}},
+			}},
+			want: "mariadb",
+		},
+		{
+			name:   "dependency not listed in Chart.yaml",
+			parent: &chart.Chart{Metadata: &chart.Metadata{}},
+			want:   "mariadb",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := dependencyAlias(tc.parent, dep)
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package helm
+
+import (
+	"fmt"
+	"os"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/strvals"
+)
+
+// ValueOptions mirrors helm.sh/helm/v3/pkg/cli/values.Options. It captures
+// the same Helm-style value overrides the `helm` CLI accepts so helmper's
+// config file can express `image.tag=v1.2.3`-style overrides instead of
+// requiring a full alternative values file.
+type ValueOptions struct {
+	ValueFiles   []string // -f/--values
+	StringValues []string // --set-string
+	Values       []string // --set
+	FileValues   []string // --set-file
+	JSONValues   []string // --set-json
+}
+
+// MergeValues merges defaults (typically chartutil.ReadValues of the chart's
+// own values.yaml) with o's value files and --set-style overrides, applied
+// in the same precedence order as the helm CLI: value files first (in
+// order), then --set, then --set-string, then --set-file, then --set-json,
+// each later source taking priority over the last.
+func (o ValueOptions) MergeValues(defaults map[string]any) (map[string]any, error) {
+	// Deep-copy defaults: the strvals.ParseInto* calls below mutate nested
+	// maps in place, and a shallow copy would leave those nested maps shared
+	// with (and corrupted in) the caller's defaults.
+	base := deepCopyValueMap(defaults)
+
+	for _, filePath := range o.ValueFiles {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read values file %s: %w", filePath, err)
+		}
+		currentMap, err := chartutil.ReadValues(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse values file %s: %w", filePath, err)
+		}
+		base = mergeValueMaps(base, currentMap)
+	}
+
+	for _, value := range o.Values {
+		if err := strvals.ParseInto(value, base); err != nil {
+			return nil, fmt.Errorf("failed parsing --set data %s: %w", value, err)
+		}
+	}
+
+	for _, value := range o.StringValues {
+		if err := strvals.ParseIntoString(value, base); err != nil {
+			return nil, fmt.Errorf("failed parsing --set-string data %s: %w", value, err)
+		}
+	}
+
+	for _, value := range o.FileValues {
+		reader := func(rs []rune) (any, error) {
+			data, err := os.ReadFile(string(rs))
+			return string(data), err
+		}
+		if err := strvals.ParseIntoFile(value, base, reader); err != nil {
+			return nil, fmt.Errorf("failed parsing --set-file data %s: %w", value, err)
+		}
+	}
+
+	for _, value := range o.JSONValues {
+		if err := strvals.ParseJSON(value, base); err != nil {
+			return nil, fmt.Errorf("failed parsing --set-json data %s: %w", value, err)
+		}
+	}
+
+	return base, nil
+}
+
+// mergeValueMaps deep-merges src over dst, returning a new map. Matches
+// Helm's own values-merge semantics: nested maps are merged key by key,
+// any other value in src simply overwrites dst. Every nested map placed into
+// the result, whether merged or taken from src/dst as-is, is deep-copied so
+// the result shares no nested map with either input.
+func mergeValueMaps(dst, src map[string]any) map[string]any {
+	out := deepCopyValueMap(dst)
+
+	for k, v := range src {
+		if nextMap, ok := v.(map[string]any); ok {
+			if existing, ok := out[k].(map[string]any); ok {
+				out[k] = mergeValueMaps(existing, nextMap)
+				continue
+			}
+			out[k] = deepCopyValueMap(nextMap)
+			continue
+		}
+		out[k] = v
+	}
+
+	return out
+}
+
+// deepCopyValueMap recursively copies m so the result shares no nested map
+// with m.
+func deepCopyValueMap(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		if nested, ok := v.(map[string]any); ok {
+			out[k] = deepCopyValueMap(nested)
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
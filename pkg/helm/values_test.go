@@ -0,0 +1,49 @@
+package helm
+
+import "testing"
+
+func TestMergeValueMaps(t *testing.T) {
+	dst := map[string]any{
+		"image":    map[string]any{"repository": "nginx", "tag": "1.0"},
+		"replicas": 1,
+	}
+	src := map[string]any{
+		"image": map[string]any{"tag": "2.0"},
+		"name":  "web",
+	}
+
+	got := mergeValueMaps(dst, src)
+
+	wantImage := got["image"].(map[string]any)
+	if wantImage["repository"] != "nginx" {
+		t.Errorf("repository: got %v, want nginx (should be preserved from dst)", wantImage["repository"])
+	}
+	if wantImage["tag"] != "2.0" {
+		t.Errorf("tag: got %v, want 2.0 (src should win)", wantImage["tag"])
+	}
+	if got["replicas"] != 1 {
+		t.Errorf("replicas: got %v, want 1", got["replicas"])
+	}
+	if got["name"] != "web" {
+		t.Errorf("name: got %v, want web", got["name"])
+	}
+
+	if dst["image"].(map[string]any)["tag"] != "1.0" {
+		t.Errorf("mergeValueMaps must not mutate dst's nested maps in place")
+	}
+}
+
+func TestMergeValuesDeepCopiesDefaults(t *testing.T) {
+	defaults := map[string]any{
+		"image": map[string]any{"tag": "1.0"},
+	}
+
+	o := ValueOptions{Values: []string{"image.tag=2.0"}}
+	if _, err := o.MergeValues(defaults); err != nil {
+		t.Fatalf("MergeValues: %v", err)
+	}
+
+	if defaults["image"].(map[string]any)["tag"] != "1.0" {
+		t.Errorf("MergeValues must not mutate the caller's defaults map")
+	}
+}